@@ -1,7 +1,10 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
@@ -9,15 +12,22 @@ import (
 	"strings"
 	"time"
 
-	"github.com/cenkalti/log"
+	"github.com/BatuAksoy/efes/logging"
 )
 
+// requestIDHeader carries the request ID generated in Client.request so
+// the tracker/server can echo and log it, tracing a single upload across
+// all three components.
+const requestIDHeader = "X-Efes-Request-ID"
+
 // Client is for reading and writing files on Efes.
 type Client struct {
-	config     *Config
-	log        log.Logger
-	trackerURL *url.URL
-	httpClient http.Client
+	config        *Config
+	log           logging.Logger
+	trackerURL    *url.URL
+	httpClient    http.Client
+	readDeadline  time.Time
+	writeDeadline time.Time
 }
 
 // NewClient creates a new Client.
@@ -26,19 +36,37 @@ func NewClient(cfg *Config) (*Client, error) {
 	if err != nil {
 		return nil, err
 	}
+	l := logging.New("client", logging.ParseFormat(cfg.LogFormat))
+	l.SetLevel(logging.ParseLevel(cfg.Client.LogLevel))
+	logging.Register("client", l)
 	c := &Client{
 		config:     cfg,
 		trackerURL: u,
-		log:        log.NewLogger("client"),
+		log:        l,
 	}
 	c.httpClient.Timeout = time.Duration(cfg.Client.SendTimeout)
-	if cfg.Debug {
-		c.log.SetLevel(log.DEBUG)
-	}
 	return c, nil
 }
 
-func (c *Client) request(method, urlPath string, params url.Values, response interface{}) (*http.Response, error) {
+// newRequestID generates a UUIDv4 to correlate a single call across the
+// Client, tracker and server in logs.
+func newRequestID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // variant 10
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+func (c *Client) request(ctx context.Context, method, urlPath string, params url.Values, response interface{}) (*http.Response, error) {
+	requestID, err := newRequestID()
+	if err != nil {
+		return nil, err
+	}
+	log := c.log.With("request_id", requestID)
+
 	var reqBody io.Reader
 	if method == http.MethodPost {
 		reqBody = strings.NewReader(params.Encode())
@@ -48,14 +76,15 @@ func (c *Client) request(method, urlPath string, params url.Values, response int
 	if method == http.MethodGet {
 		newURL.RawQuery = params.Encode()
 	}
-	req, err := http.NewRequest(method, newURL.String(), reqBody)
+	req, err := http.NewRequestWithContext(ctx, method, newURL.String(), reqBody)
 	if err != nil {
 		return nil, err
 	}
 	if method == http.MethodPost {
 		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	}
-	c.log.Debugln("request method:", req.Method, "path:", req.URL.Path, "params:", params)
+	req.Header.Set(requestIDHeader, requestID)
+	log.Debug("sending request", "method", req.Method, "path", req.URL.Path, "params", params.Encode())
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, err
@@ -71,21 +100,115 @@ func (c *Client) request(method, urlPath string, params url.Values, response int
 	if err != nil {
 		return resp, err
 	}
-	c.log.Debugf("%s got response: %#v", req.URL.Path, response)
+	log.Debug("got response", "path", req.URL.Path, "response", fmt.Sprintf("%#v", response))
 	return resp, nil
 }
 
+// readContext derives a context bounded by the read deadline set with
+// SetReadDeadline, if any, mirroring the deadlineTimer pattern used in
+// gonet so a hung read can be interrupted without tearing down the whole
+// Client.
+func (c *Client) readContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.readDeadline.IsZero() {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, c.readDeadline)
+}
+
+// writeContext is the write-path equivalent of readContext, bounded by the
+// deadline set with SetWriteDeadline.
+func (c *Client) writeContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.writeDeadline.IsZero() {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, c.writeDeadline)
+}
+
+// SetReadDeadline sets an absolute deadline after which read operations
+// (Exists, Status, getPath) are cancelled via their derived context,
+// regardless of the global httpClient.Timeout. A zero Time clears it.
+func (c *Client) SetReadDeadline(t time.Time) {
+	c.readDeadline = t
+}
+
+// SetWriteDeadline sets an absolute deadline after which write operations
+// (Delete, CreateOpenWithPlacement) are cancelled via their derived
+// context. A zero Time clears it.
+func (c *Client) SetWriteDeadline(t time.Time) {
+	c.writeDeadline = t
+}
+
 // Delete the key on Efes.
 func (c *Client) Delete(key string) error {
+	return c.DeleteContext(context.Background(), key)
+}
+
+// DeleteContext is Delete with a caller-supplied context for cancellation,
+// bounded further by any deadline set with SetWriteDeadline.
+func (c *Client) DeleteContext(ctx context.Context, key string) error {
+	ctx, cancel := c.writeContext(ctx)
+	defer cancel()
 	form := url.Values{}
 	form.Add("key", key)
-	_, err := c.request(http.MethodPost, "delete", form, nil)
+	_, err := c.request(ctx, http.MethodPost, "delete", form, nil)
 	return err
 }
 
+// CreateOpenResult is the tracker's reply to a create-open request,
+// including the failure-domain attributes of the device it resolved the
+// write to, so callers such as Status can render them.
+type CreateOpenResult struct {
+	Devid int64  `json:"devid"`
+	Host  string `json:"host"`
+	Zone  string `json:"zone"`
+	Rack  string `json:"rack"`
+}
+
+// CreateOpenWithPlacement sends a create-open request for key to the
+// tracker, together with spread/affinity/anti-affinity placement rules for the tracker's device
+// selector to honor, e.g. []string{"spread: zone", "affinity: host=foo weight=50"}.
+func (c *Client) CreateOpenWithPlacement(key string, rules []string) (*CreateOpenResult, error) {
+	return c.CreateOpenWithPlacementContext(context.Background(), key, rules)
+}
+
+// CreateOpenWithPlacementContext is CreateOpenWithPlacement with a
+// caller-supplied context, bounded further by any deadline set with
+// SetWriteDeadline.
+func (c *Client) CreateOpenWithPlacementContext(ctx context.Context, key string, rules []string) (*CreateOpenResult, error) {
+	spec, err := ParsePlacementSpec(rules)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := c.writeContext(ctx)
+	defer cancel()
+	form := url.Values{}
+	form.Add("key", key)
+	if spec.Spread != "" {
+		form.Add("spread", spec.Spread)
+	}
+	for _, a := range spec.Affinities {
+		form.Add("affinity", fmt.Sprintf("%s=%s weight=%d", a.Attribute, a.Value, a.Weight))
+	}
+	for _, attr := range spec.AntiAffinities {
+		form.Add("anti_affinity", attr)
+	}
+	var result CreateOpenResult
+	_, err = c.request(ctx, http.MethodPost, "create-open", form, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
 // Exists checks the existence of a key on Efes.
 func (c *Client) Exists(key string) (bool, error) {
-	_, err := c.getPath(key)
+	return c.ExistsContext(context.Background(), key)
+}
+
+// ExistsContext is Exists with a caller-supplied context for cancellation,
+// bounded further by any deadline set with SetReadDeadline.
+func (c *Client) ExistsContext(ctx context.Context, key string) (bool, error) {
+	_, err := c.getPath(ctx, key)
 	if err != nil {
 		if errc, ok := err.(*ClientError); ok && errc.Code == http.StatusNotFound {
 			return false, nil
@@ -94,3 +217,20 @@ func (c *Client) Exists(key string) (bool, error) {
 	}
 	return true, nil
 }
+
+// getPath looks up the storage path for key on the tracker, bounded by any
+// deadline set with SetReadDeadline.
+func (c *Client) getPath(ctx context.Context, key string) (string, error) {
+	ctx, cancel := c.readContext(ctx)
+	defer cancel()
+	form := url.Values{}
+	form.Add("key", key)
+	var result struct {
+		Path string `json:"path"`
+	}
+	_, err := c.request(ctx, http.MethodGet, "get-path", form, &result)
+	if err != nil {
+		return "", err
+	}
+	return result.Path, nil
+}
@@ -1,10 +1,16 @@
 package main
 
-import "github.com/BurntSushi/toml"
+import (
+	"time"
+
+	"github.com/BatuAksoy/efes/storage"
+	"github.com/BurntSushi/toml"
+)
 
 // TrackerConfig holds configuration values for Tracker.
 type TrackerConfig struct {
 	Debug           bool   `toml:"debug"`
+	LogLevel        string `toml:"log_level"`
 	ListenAddress   string `toml:"listen_address"`
 	ShutdownTimeout uint32 `toml:"shutdown_timeout"`
 	TempfileTooOld  uint32 `toml:"tempfile_too_old"`
@@ -15,16 +21,37 @@ type DatabaseConfig struct {
 	DSN string `toml:"dsn"`
 }
 
+// ClientConfig holds configuration values for Client.
+type ClientConfig struct {
+	TrackerURL  string        `toml:"tracker_url"`
+	SendTimeout time.Duration `toml:"send_timeout"`
+	LogLevel    string        `toml:"log_level"`
+}
+
 // ServerConfig holds configuration values for Server.
 type ServerConfig struct {
-	Debug bool `toml:"debug"`
+	Debug    bool             `toml:"debug"`
+	LogLevel string           `toml:"log_level"`
+	Devices  []storage.Config `toml:"devices"`
+}
+
+// HostConfig describes the failure domain a host lives in, so the tracker's
+// device selector can honor spread and (anti-)affinity placement rules.
+type HostConfig struct {
+	Hostname string `toml:"hostname"`
+	Zone     string `toml:"zone"`
+	Rack     string `toml:"rack"`
 }
 
 // Config holds configuration values for all Efes components.
 type Config struct {
-	Tracker  TrackerConfig
-	Server   ServerConfig
-	Database DatabaseConfig
+	Tracker   TrackerConfig
+	Server    ServerConfig
+	Client    ClientConfig
+	Database  DatabaseConfig
+	Metrics   MetricsConfig
+	Hosts     []HostConfig
+	LogFormat string `toml:"log_format"` // "text" (default) or "json"
 }
 
 var defaultConfig = Config{
@@ -0,0 +1,250 @@
+// Package logging is a small structured-logging façade used by the
+// tracker, server and Client, so a log line carries key/value pairs (and
+// optionally renders as JSON) instead of the plain strings cenkalti/log
+// produces. Call sites that only need the old Debugln/Debugf/Warningln
+// style keep working unchanged; new call sites can attach structured
+// fields such as request_id with With.
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level is a log severity, ordered from most to least verbose.
+type Level int
+
+// Levels, ordered the same way as cenkalti/log's.
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarning
+	LevelError
+)
+
+// ParseLevel maps a TOML LogLevel value ("DEBUG", "INFO", "WARNING",
+// "ERROR") to a Level, defaulting to LevelInfo for anything else.
+func ParseLevel(s string) Level {
+	switch strings.ToUpper(s) {
+	case "DEBUG":
+		return LevelDebug
+	case "WARNING":
+		return LevelWarning
+	case "ERROR":
+		return LevelError
+	default:
+		return LevelInfo
+	}
+}
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelWarning:
+		return "WARNING"
+	case LevelError:
+		return "ERROR"
+	default:
+		return "INFO"
+	}
+}
+
+// Format selects how log lines are rendered.
+type Format int
+
+// Formats supported by New.
+const (
+	FormatText Format = iota
+	FormatJSON
+)
+
+// ParseFormat maps a Config.LogFormat TOML value ("text"/"json") to a
+// Format, defaulting to FormatText.
+func ParseFormat(s string) Format {
+	if strings.EqualFold(s, "json") {
+		return FormatJSON
+	}
+	return FormatText
+}
+
+// Logger is a leveled, structured logger. Every line carries a message and
+// an even list of key/value pairs. With returns a child Logger that always
+// includes a fixed set of pairs, so a request ID attached once is carried
+// through every subsequent log call on that child.
+type Logger interface {
+	Debug(msg string, kv ...interface{})
+	Info(msg string, kv ...interface{})
+	Warning(msg string, kv ...interface{})
+	Error(msg string, kv ...interface{})
+	With(kv ...interface{}) Logger
+	SetLevel(Level)
+	Level() Level
+	Name() string
+
+	// Debugln, Debugf and Warningln mirror cenkalti/log's API so existing
+	// call sites can adopt this package without rewriting every call.
+	Debugln(args ...interface{})
+	Debugf(format string, args ...interface{})
+	Warningln(args ...interface{})
+}
+
+type logger struct {
+	mu     *sync.Mutex // shared with every logger derived from the same New call, so SetLevel affects them all
+	level  *Level
+	name   string
+	format Format
+	out    io.Writer
+	fields []interface{}
+}
+
+// New returns a Logger that writes to os.Stderr in the given format.
+func New(name string, format Format) Logger {
+	level := LevelInfo
+	return &logger{
+		mu:     &sync.Mutex{},
+		level:  &level,
+		name:   name,
+		format: format,
+		out:    os.Stderr,
+	}
+}
+
+func (l *logger) With(kv ...interface{}) Logger {
+	fields := make([]interface{}, 0, len(l.fields)+len(kv))
+	fields = append(fields, l.fields...)
+	fields = append(fields, kv...)
+	return &logger{mu: l.mu, level: l.level, name: l.name, format: l.format, out: l.out, fields: fields}
+}
+
+func (l *logger) SetLevel(level Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	*l.level = level
+}
+
+func (l *logger) Level() Level {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return *l.level
+}
+
+func (l *logger) Name() string { return l.name }
+
+func (l *logger) log(level Level, msg string, kv []interface{}) {
+	if level < l.Level() {
+		return
+	}
+	fields := make([]interface{}, 0, len(l.fields)+len(kv))
+	fields = append(fields, l.fields...)
+	fields = append(fields, kv...)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	switch l.format {
+	case FormatJSON:
+		l.writeJSON(level, msg, fields)
+	default:
+		l.writeText(level, msg, fields)
+	}
+}
+
+func (l *logger) writeText(level Level, msg string, fields []interface{}) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s [%s] %s: %s", time.Now().UTC().Format(time.RFC3339), level, l.name, msg)
+	for i := 0; i+1 < len(fields); i += 2 {
+		fmt.Fprintf(&b, " %v=%v", fields[i], fields[i+1])
+	}
+	fmt.Fprintln(l.out, b.String())
+}
+
+func (l *logger) writeJSON(level Level, msg string, fields []interface{}) {
+	entry := map[string]interface{}{
+		"time":  time.Now().UTC().Format(time.RFC3339),
+		"level": level.String(),
+		"name":  l.name,
+		"msg":   msg,
+	}
+	for i := 0; i+1 < len(fields); i += 2 {
+		if key, ok := fields[i].(string); ok {
+			entry[key] = fields[i+1]
+		}
+	}
+	enc := json.NewEncoder(l.out)
+	_ = enc.Encode(entry)
+}
+
+func (l *logger) Debug(msg string, kv ...interface{})   { l.log(LevelDebug, msg, kv) }
+func (l *logger) Info(msg string, kv ...interface{})    { l.log(LevelInfo, msg, kv) }
+func (l *logger) Warning(msg string, kv ...interface{}) { l.log(LevelWarning, msg, kv) }
+func (l *logger) Error(msg string, kv ...interface{})   { l.log(LevelError, msg, kv) }
+
+func (l *logger) Debugln(args ...interface{}) {
+	l.log(LevelDebug, strings.TrimSpace(fmt.Sprintln(args...)), nil)
+}
+
+func (l *logger) Debugf(format string, args ...interface{}) {
+	l.log(LevelDebug, fmt.Sprintf(format, args...), nil)
+}
+
+func (l *logger) Warningln(args ...interface{}) {
+	l.log(LevelWarning, strings.TrimSpace(fmt.Sprintln(args...)), nil)
+}
+
+// registry lets the /debug/log-level admin endpoint change a running
+// component's level by name without a restart.
+var (
+	registryMu sync.Mutex
+	registry   = map[string]Logger{}
+)
+
+// Register makes l available to SetLevelByName under name, which New's
+// callers should do once per component (e.g. "tracker", "server", "client").
+func Register(name string, l Logger) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = l
+}
+
+// SetLevelByName changes the level of a previously Registered logger at
+// runtime, e.g. from the /debug/log-level admin endpoint.
+func SetLevelByName(name, levelName string) error {
+	registryMu.Lock()
+	l, ok := registry[name]
+	registryMu.Unlock()
+	if !ok {
+		return fmt.Errorf("logging: no logger registered with name %q", name)
+	}
+	l.SetLevel(ParseLevel(levelName))
+	return nil
+}
+
+// AdminHandler serves the /debug/log-level admin endpoint: POST
+// ?component=tracker&level=DEBUG changes a registered component's level
+// at runtime, GET without a component lists every registered component
+// and its current level.
+func AdminHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		component := r.URL.Query().Get("component")
+		level := r.URL.Query().Get("level")
+		if r.Method == http.MethodPost && component != "" && level != "" {
+			if err := SetLevelByName(component, level); err != nil {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			fmt.Fprintf(w, "%s level set to %s\n", component, ParseLevel(level))
+			return
+		}
+		registryMu.Lock()
+		defer registryMu.Unlock()
+		for name, l := range registry {
+			fmt.Fprintf(w, "%s=%s\n", name, l.Level())
+		}
+	}
+}
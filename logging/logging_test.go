@@ -0,0 +1,75 @@
+package logging
+
+import "testing"
+
+func TestParseLevel(t *testing.T) {
+	cases := []struct {
+		in   string
+		want Level
+	}{
+		{"DEBUG", LevelDebug},
+		{"debug", LevelDebug},
+		{"WARNING", LevelWarning},
+		{"ERROR", LevelError},
+		{"INFO", LevelInfo},
+		{"", LevelInfo},
+		{"bogus", LevelInfo},
+	}
+	for _, tc := range cases {
+		if got := ParseLevel(tc.in); got != tc.want {
+			t.Errorf("ParseLevel(%q) = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	cases := []struct {
+		in   string
+		want Format
+	}{
+		{"json", FormatJSON},
+		{"JSON", FormatJSON},
+		{"text", FormatText},
+		{"", FormatText},
+		{"bogus", FormatText},
+	}
+	for _, tc := range cases {
+		if got := ParseFormat(tc.in); got != tc.want {
+			t.Errorf("ParseFormat(%q) = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestLoggerWithCarriesFieldsAndRespectsLevel(t *testing.T) {
+	l := New("test", FormatText)
+	child := l.With("request_id", "abc")
+	if child.Name() != "test" {
+		t.Fatalf("child.Name() = %q, want %q", child.Name(), "test")
+	}
+	if fields := child.(*logger).fields; len(fields) != 2 || fields[0] != "request_id" || fields[1] != "abc" {
+		t.Fatalf("child fields = %v, want [request_id abc]", fields)
+	}
+
+	l.SetLevel(LevelWarning)
+	if child.Level() != LevelWarning {
+		t.Fatalf("child.Level() = %v after parent.SetLevel, want %v (level is shared)", child.Level(), LevelWarning)
+	}
+}
+
+func TestSetLevelByNameUnknownComponent(t *testing.T) {
+	if err := SetLevelByName("does-not-exist", "DEBUG"); err == nil {
+		t.Fatal("SetLevelByName for an unregistered component returned nil error, want an error")
+	}
+}
+
+func TestSetLevelByNameRegistered(t *testing.T) {
+	l := New("registered-component", FormatText)
+	Register("registered-component", l)
+
+	if err := SetLevelByName("registered-component", "ERROR"); err != nil {
+		t.Fatalf("SetLevelByName returned error: %v", err)
+	}
+	if l.Level() != LevelError {
+		t.Fatalf("l.Level() = %v, want %v", l.Level(), LevelError)
+	}
+}
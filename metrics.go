@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MetricsConfig controls the Prometheus-compatible /metrics endpoint
+// exposed by the tracker and the per-host server.
+type MetricsConfig struct {
+	Enabled       bool   `toml:"enabled"`
+	ListenAddress string `toml:"listen_address"`
+}
+
+// deviceMetrics is the Prometheus rendering of a single device row, the
+// same data efesStatus.Print formats into a table.
+type deviceMetrics struct {
+	Host          string
+	Devid         int64
+	Status        string
+	BytesTotal    *int64
+	BytesUsed     *int64
+	BytesFree     *int64
+	IoUtilization *int64
+	UpdatedAt     time.Time
+}
+
+// writeDeviceMetrics renders device-level gauges in the Prometheus text
+// exposition format, labeled by host, device_id and status so a single
+// device can be sliced across dashboards without log scraping.
+func writeDeviceMetrics(w io.Writer, devices []deviceMetrics) {
+	fmt.Fprintln(w, "# HELP efes_device_bytes_total Total capacity of the device in bytes.")
+	fmt.Fprintln(w, "# TYPE efes_device_bytes_total gauge")
+	for _, d := range devices {
+		writeGauge(w, "efes_device_bytes_total", d, d.BytesTotal)
+	}
+	fmt.Fprintln(w, "# HELP efes_device_bytes_used Used space on the device in bytes.")
+	fmt.Fprintln(w, "# TYPE efes_device_bytes_used gauge")
+	for _, d := range devices {
+		writeGauge(w, "efes_device_bytes_used", d, d.BytesUsed)
+	}
+	fmt.Fprintln(w, "# HELP efes_device_bytes_free Free space on the device in bytes.")
+	fmt.Fprintln(w, "# TYPE efes_device_bytes_free gauge")
+	for _, d := range devices {
+		writeGauge(w, "efes_device_bytes_free", d, d.BytesFree)
+	}
+	fmt.Fprintln(w, "# HELP efes_device_io_utilization Device IO utilization percentage.")
+	fmt.Fprintln(w, "# TYPE efes_device_io_utilization gauge")
+	for _, d := range devices {
+		writeGauge(w, "efes_device_io_utilization", d, d.IoUtilization)
+	}
+	fmt.Fprintln(w, "# HELP efes_device_last_update_seconds Seconds since the device last reported in.")
+	fmt.Fprintln(w, "# TYPE efes_device_last_update_seconds gauge")
+	now := time.Now().UTC()
+	for _, d := range devices {
+		fmt.Fprintf(w, "efes_device_last_update_seconds{host=%q,device_id=\"%d\",status=%q} %f\n",
+			d.Host, d.Devid, d.Status, now.Sub(d.UpdatedAt).Seconds())
+	}
+}
+
+func writeGauge(w io.Writer, name string, d deviceMetrics, value *int64) {
+	if value == nil {
+		return
+	}
+	fmt.Fprintf(w, "%s{host=%q,device_id=\"%d\",status=%q} %d\n", name, d.Host, d.Devid, d.Status, *value)
+}
+
+// trackerMetrics holds the tracker-wide counters and gauges served on
+// /metrics, alongside the per-device gauges.
+type trackerMetrics struct {
+	RequestsTotal     map[string]int64 // keyed by request path
+	DeleteCallsTotal  int64
+	CreateCallsTotal  int64
+	GetCallsTotal     int64
+	TempfileAgeBucket map[float64]int64 // cumulative counts, keyed by bucket upper bound (le), in seconds
+	TempfileAgeSum    float64           // sum of all observed tempfile ages, in seconds
+	TempfileAgeCount  int64             // total number of tempfile age observations
+	HostsUp           map[string]bool
+	Devices           []deviceMetrics
+}
+
+func (m *trackerMetrics) writeTo(w io.Writer) {
+	fmt.Fprintln(w, "# HELP efes_tracker_requests_total Total tracker requests handled, by path.")
+	fmt.Fprintln(w, "# TYPE efes_tracker_requests_total counter")
+	for path, count := range m.RequestsTotal {
+		fmt.Fprintf(w, "efes_tracker_requests_total{path=%q} %d\n", path, count)
+	}
+
+	fmt.Fprintln(w, "# HELP efes_tracker_delete_calls_total Total delete calls handled.")
+	fmt.Fprintln(w, "# TYPE efes_tracker_delete_calls_total counter")
+	fmt.Fprintf(w, "efes_tracker_delete_calls_total %d\n", m.DeleteCallsTotal)
+
+	fmt.Fprintln(w, "# HELP efes_tracker_create_calls_total Total create-open calls handled.")
+	fmt.Fprintln(w, "# TYPE efes_tracker_create_calls_total counter")
+	fmt.Fprintf(w, "efes_tracker_create_calls_total %d\n", m.CreateCallsTotal)
+
+	fmt.Fprintln(w, "# HELP efes_tracker_get_calls_total Total get-path calls handled.")
+	fmt.Fprintln(w, "# TYPE efes_tracker_get_calls_total counter")
+	fmt.Fprintf(w, "efes_tracker_get_calls_total %d\n", m.GetCallsTotal)
+
+	fmt.Fprintln(w, "# HELP efes_tracker_tempfile_age_seconds Age of tempfiles pending cleanup.")
+	fmt.Fprintln(w, "# TYPE efes_tracker_tempfile_age_seconds histogram")
+	var buckets []float64
+	for b := range m.TempfileAgeBucket {
+		buckets = append(buckets, b)
+	}
+	sort.Float64s(buckets)
+	for _, b := range buckets {
+		fmt.Fprintf(w, "efes_tracker_tempfile_age_seconds_bucket{le=%q} %d\n", strconv.FormatFloat(b, 'f', -1, 64), m.TempfileAgeBucket[b])
+	}
+	fmt.Fprintf(w, "efes_tracker_tempfile_age_seconds_bucket{le=\"+Inf\"} %d\n", m.TempfileAgeCount)
+	fmt.Fprintf(w, "efes_tracker_tempfile_age_seconds_sum %f\n", m.TempfileAgeSum)
+	fmt.Fprintf(w, "efes_tracker_tempfile_age_seconds_count %d\n", m.TempfileAgeCount)
+
+	fmt.Fprintln(w, "# HELP efes_host_up Whether the tracker currently considers the host up.")
+	fmt.Fprintln(w, "# TYPE efes_host_up gauge")
+	for host, up := range m.HostsUp {
+		fmt.Fprintf(w, "efes_host_up{host=%q} %s\n", host, boolToGauge(up))
+	}
+
+	writeDeviceMetrics(w, m.Devices)
+}
+
+func boolToGauge(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+// metricsHandler serves the Prometheus text exposition format for either
+// the tracker or the server, depending on which metrics func is supplied.
+func metricsHandler(collect func() *trackerMetrics) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		collect().writeTo(w)
+	}
+}
+
+// Sample is one point-in-time reading returned by Client.Metrics, keyed by
+// metric name and label set so two samples can be diffed into a rate.
+type Sample struct {
+	Name   string
+	Labels map[string]string
+	Value  float64
+}
+
+// MetricsSample is a parsed scrape of a /metrics endpoint.
+type MetricsSample struct {
+	TakenAt time.Time
+	Samples []Sample
+}
+
+// Metrics scrapes the Prometheus text format from the tracker or a
+// server's /metrics endpoint and parses it into Samples, so callers such
+// as `efes status` can take two samples and compute rates (e.g. MB/s in,
+// out) from the delta.
+func (c *Client) Metrics(url string) (*MetricsSample, error) {
+	resp, err := c.httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("efes: metrics scrape of %s failed with status %d", url, resp.StatusCode)
+	}
+	sample := &MetricsSample{TakenAt: time.Now().UTC()}
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		s, err := parseMetricLine(line)
+		if err != nil {
+			return nil, err
+		}
+		sample.Samples = append(sample.Samples, s)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return sample, nil
+}
+
+func parseMetricLine(line string) (Sample, error) {
+	nameAndLabels, valueStr, ok := strings.Cut(strings.TrimSpace(line), " ")
+	if !ok {
+		return Sample{}, fmt.Errorf("efes: invalid metric line %q", line)
+	}
+	value, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil {
+		return Sample{}, fmt.Errorf("efes: invalid metric value in %q: %w", line, err)
+	}
+	name := nameAndLabels
+	labels := map[string]string{}
+	if i := strings.IndexByte(nameAndLabels, '{'); i >= 0 {
+		name = nameAndLabels[:i]
+		labelStr := strings.TrimSuffix(nameAndLabels[i+1:], "}")
+		for _, pair := range strings.Split(labelStr, ",") {
+			k, v, ok := strings.Cut(pair, "=")
+			if !ok {
+				continue
+			}
+			labels[k] = strings.Trim(v, `"`)
+		}
+	}
+	return Sample{Name: name, Labels: labels, Value: value}, nil
+}
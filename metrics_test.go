@@ -0,0 +1,71 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMetricLine(t *testing.T) {
+	cases := []struct {
+		name    string
+		line    string
+		want    Sample
+		wantErr bool
+	}{
+		{
+			name: "no labels",
+			line: "efes_tracker_delete_calls_total 3",
+			want: Sample{Name: "efes_tracker_delete_calls_total", Labels: map[string]string{}, Value: 3},
+		},
+		{
+			name: "multiple labels",
+			line: `efes_device_bytes_total{host="a",device_id="1",status="up"} 1073741824`,
+			want: Sample{
+				Name:   "efes_device_bytes_total",
+				Labels: map[string]string{"host": "a", "device_id": "1", "status": "up"},
+				Value:  1073741824,
+			},
+		},
+		{
+			name: "float value",
+			line: `efes_tracker_tempfile_age_seconds_sum 12.5`,
+			want: Sample{Name: "efes_tracker_tempfile_age_seconds_sum", Labels: map[string]string{}, Value: 12.5},
+		},
+		{
+			name: "plus-inf label value",
+			line: `efes_tracker_tempfile_age_seconds_bucket{le="+Inf"} 7`,
+			want: Sample{
+				Name:   "efes_tracker_tempfile_age_seconds_bucket",
+				Labels: map[string]string{"le": "+Inf"},
+				Value:  7,
+			},
+		},
+		{
+			name:    "missing value",
+			line:    "efes_tracker_delete_calls_total",
+			wantErr: true,
+		},
+		{
+			name:    "non-numeric value",
+			line:    "efes_tracker_delete_calls_total not-a-number",
+			wantErr: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseMetricLine(tc.line)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("parseMetricLine(%q) = %+v, want error", tc.line, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseMetricLine(%q) returned error: %v", tc.line, err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("parseMetricLine(%q) = %+v, want %+v", tc.line, got, tc.want)
+			}
+		})
+	}
+}
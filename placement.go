@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Affinity biases the device selector towards (positive weight) or away
+// from (negative weight) candidates matching attribute=value, similar to
+// Nomad's affinity stanza.
+type Affinity struct {
+	Attribute string
+	Value     string
+	Weight    int64
+}
+
+// PlacementSpec describes how a client wants replicas of a key spread
+// across the tracker's failure domains. Spread asks the selector to
+// diversify replicas across the given attribute (e.g. "zone"), Affinities
+// bias individual candidates up or down, and AntiAffinities push replicas
+// away from concentrating on a shared attribute value.
+type PlacementSpec struct {
+	Spread         string
+	Affinities     []Affinity
+	AntiAffinities []string
+}
+
+// ParsePlacementSpec parses rules in the CLI form understood by
+// CreateOpenWithPlacement, e.g.:
+//
+//	spread: zone
+//	affinity: host=foo weight=50
+//	anti_affinity: rack
+func ParsePlacementSpec(rules []string) (*PlacementSpec, error) {
+	spec := &PlacementSpec{}
+	for _, rule := range rules {
+		kind, rest, ok := strings.Cut(rule, ":")
+		if !ok {
+			return nil, fmt.Errorf("efes: invalid placement rule %q", rule)
+		}
+		kind = strings.TrimSpace(kind)
+		rest = strings.TrimSpace(rest)
+		switch kind {
+		case "spread":
+			spec.Spread = rest
+		case "affinity":
+			affinity, err := parseAffinity(rest)
+			if err != nil {
+				return nil, err
+			}
+			spec.Affinities = append(spec.Affinities, *affinity)
+		case "anti_affinity":
+			spec.AntiAffinities = append(spec.AntiAffinities, rest)
+		default:
+			return nil, fmt.Errorf("efes: unknown placement rule kind %q", kind)
+		}
+	}
+	return spec, nil
+}
+
+func parseAffinity(rest string) (*Affinity, error) {
+	affinity := Affinity{Weight: 50}
+	for _, field := range strings.Fields(rest) {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return nil, fmt.Errorf("efes: invalid affinity field %q", field)
+		}
+		switch key {
+		case "weight":
+			w, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("efes: invalid affinity weight %q", value)
+			}
+			affinity.Weight = w
+		default:
+			affinity.Attribute = key
+			affinity.Value = value
+		}
+	}
+	if affinity.Attribute == "" {
+		return nil, fmt.Errorf("efes: affinity rule %q is missing an attribute=value pair", rest)
+	}
+	return &affinity, nil
+}
+
+// deviceCandidate is a device considered by the tracker's selector for a
+// new write, along with the failure-domain attributes needed to score it.
+type deviceCandidate struct {
+	Devid     int64
+	Host      string
+	Zone      string
+	Rack      string
+	Backend   string
+	BaseScore int64
+}
+
+// attr returns the candidate's value for a spread/affinity attribute name
+// such as "host", "zone", "rack" or "backend".
+func (d deviceCandidate) attr(name string) string {
+	switch name {
+	case "host":
+		return d.Host
+	case "zone":
+		return d.Zone
+	case "rack":
+		return d.Rack
+	case "backend":
+		return d.Backend
+	default:
+		return ""
+	}
+}
+
+// antiAffinityPenalty is subtracted from a candidate's score once per
+// replica already living in its value of an anti-affinity attribute.
+const antiAffinityPenalty = 50
+
+// scoreDevices scores each candidate as
+// base_score + Σ affinity_weights − Σ anti_affinity_penalties and returns
+// the scores keyed by Devid. replicaCountByAttr tracks how many replicas of
+// the key already live in each attribute value seen across spec.Spread and
+// spec.AntiAffinities, so that among top-scoring devices, selectDevice
+// can prefer the zone currently holding the fewest replicas, and so the
+// anti-affinity penalty actually grows with existing concentration on a
+// shared attribute value rather than applying uniformly to every
+// candidate that merely has that attribute set.
+func scoreDevices(candidates []deviceCandidate, spec PlacementSpec, replicaCountByAttr map[string]int) map[int64]int64 {
+	scores := make(map[int64]int64, len(candidates))
+	for _, c := range candidates {
+		score := c.BaseScore
+		for _, a := range spec.Affinities {
+			if c.attr(a.Attribute) == a.Value {
+				score += a.Weight
+			}
+		}
+		for _, attr := range spec.AntiAffinities {
+			if value := c.attr(attr); value != "" {
+				score -= antiAffinityPenalty * int64(replicaCountByAttr[value])
+			}
+		}
+		scores[c.Devid] = score
+	}
+	return scores
+}
+
+// selectDevice picks the best candidate for a new replica: the highest
+// scoring devices are grouped together, then among that group the one in
+// the spread attribute's least-represented value wins, so replicas spread
+// across zones/racks rather than piling onto a single best-scoring one.
+// When fewer than two distinct spread values exist among the top
+// candidates, selectDevice falls back to plain score order.
+func selectDevice(candidates []deviceCandidate, spec PlacementSpec, replicaCountByAttr map[string]int) (deviceCandidate, bool) {
+	if len(candidates) == 0 {
+		return deviceCandidate{}, false
+	}
+	scores := scoreDevices(candidates, spec, replicaCountByAttr)
+
+	var best int64
+	first := true
+	for _, c := range candidates {
+		if first || scores[c.Devid] > best {
+			best = scores[c.Devid]
+			first = false
+		}
+	}
+
+	var top []deviceCandidate
+	for _, c := range candidates {
+		if scores[c.Devid] == best {
+			top = append(top, c)
+		}
+	}
+
+	if spec.Spread == "" {
+		return top[0], true
+	}
+
+	distinct := make(map[string]bool)
+	for _, c := range top {
+		distinct[c.attr(spec.Spread)] = true
+	}
+	if len(distinct) < 2 {
+		return top[0], true
+	}
+
+	winner := top[0]
+	winnerCount := replicaCountByAttr[winner.attr(spec.Spread)]
+	for _, c := range top[1:] {
+		if count := replicaCountByAttr[c.attr(spec.Spread)]; count < winnerCount {
+			winner = c
+			winnerCount = count
+		}
+	}
+	return winner, true
+}
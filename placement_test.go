@@ -0,0 +1,142 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePlacementSpec(t *testing.T) {
+	cases := []struct {
+		name    string
+		rules   []string
+		want    *PlacementSpec
+		wantErr bool
+	}{
+		{
+			name:  "spread only",
+			rules: []string{"spread: zone"},
+			want:  &PlacementSpec{Spread: "zone"},
+		},
+		{
+			name:  "affinity with explicit weight",
+			rules: []string{"affinity: host=foo weight=50"},
+			want:  &PlacementSpec{Affinities: []Affinity{{Attribute: "host", Value: "foo", Weight: 50}}},
+		},
+		{
+			name:  "affinity defaults to weight 50",
+			rules: []string{"affinity: host=foo"},
+			want:  &PlacementSpec{Affinities: []Affinity{{Attribute: "host", Value: "foo", Weight: 50}}},
+		},
+		{
+			name:  "anti_affinity",
+			rules: []string{"anti_affinity: rack"},
+			want:  &PlacementSpec{AntiAffinities: []string{"rack"}},
+		},
+		{
+			name:  "combined rules",
+			rules: []string{"spread: zone", "affinity: host=foo weight=10", "anti_affinity: rack"},
+			want: &PlacementSpec{
+				Spread:         "zone",
+				Affinities:     []Affinity{{Attribute: "host", Value: "foo", Weight: 10}},
+				AntiAffinities: []string{"rack"},
+			},
+		},
+		{
+			name:    "missing colon",
+			rules:   []string{"spread zone"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown kind",
+			rules:   []string{"foo: bar"},
+			wantErr: true,
+		},
+		{
+			name:    "affinity without attribute=value",
+			rules:   []string{"affinity: weight=10"},
+			wantErr: true,
+		},
+		{
+			name:    "affinity with invalid weight",
+			rules:   []string{"affinity: host=foo weight=abc"},
+			wantErr: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParsePlacementSpec(tc.rules)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("ParsePlacementSpec(%v) = %v, want error", tc.rules, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParsePlacementSpec(%v) returned error: %v", tc.rules, err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("ParsePlacementSpec(%v) = %+v, want %+v", tc.rules, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSelectDeviceSpreadsAcrossLeastRepresentedZone(t *testing.T) {
+	candidates := []deviceCandidate{
+		{Devid: 1, Zone: "a"},
+		{Devid: 2, Zone: "b"},
+		{Devid: 3, Zone: "a"},
+	}
+	spec := PlacementSpec{Spread: "zone"}
+	replicaCountByAttr := map[string]int{"a": 2, "b": 0}
+
+	got, ok := selectDevice(candidates, spec, replicaCountByAttr)
+	if !ok {
+		t.Fatal("selectDevice returned ok=false")
+	}
+	if got.Zone != "b" {
+		t.Fatalf("selectDevice picked zone %q, want the least-represented zone %q", got.Zone, "b")
+	}
+}
+
+func TestSelectDeviceFallsBackToScoreWhenOnlyOneZone(t *testing.T) {
+	candidates := []deviceCandidate{
+		{Devid: 1, Zone: "a", BaseScore: 10},
+		{Devid: 2, Zone: "a", BaseScore: 20},
+	}
+	spec := PlacementSpec{Spread: "zone"}
+
+	got, ok := selectDevice(candidates, spec, map[string]int{})
+	if !ok {
+		t.Fatal("selectDevice returned ok=false")
+	}
+	if got.Devid != 2 {
+		t.Fatalf("selectDevice picked devid %d, want the higher-scoring device 2", got.Devid)
+	}
+}
+
+func TestSelectDeviceAntiAffinityPenaltyScalesWithConcentration(t *testing.T) {
+	candidates := []deviceCandidate{
+		{Devid: 1, Rack: "r1", BaseScore: 100},
+		{Devid: 2, Rack: "r2", BaseScore: 100},
+	}
+	spec := PlacementSpec{AntiAffinities: []string{"rack"}}
+	// r1 already holds 3 replicas, r2 holds none, so r1's score should be
+	// penalized far more heavily than r2's despite an identical base score.
+	replicaCountByAttr := map[string]int{"r1": 3, "r2": 0}
+
+	got, ok := selectDevice(candidates, spec, replicaCountByAttr)
+	if !ok {
+		t.Fatal("selectDevice returned ok=false")
+	}
+	if got.Devid != 2 {
+		t.Fatalf("selectDevice picked devid %d, want the less-concentrated rack's device 2", got.Devid)
+	}
+}
+
+func TestSelectDeviceNoCandidates(t *testing.T) {
+	_, ok := selectDevice(nil, PlacementSpec{}, nil)
+	if ok {
+		t.Fatal("selectDevice returned ok=true for an empty candidate list")
+	}
+}
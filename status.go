@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"net/http"
 	"os"
 	"sort"
@@ -19,6 +20,8 @@ type deviceStatus struct {
 	Device
 	Hostname   string
 	HostStatus string
+	Zone       string
+	Rack       string
 	UpdatedAt  time.Time
 }
 
@@ -92,6 +95,9 @@ func (s *efesStatus) Print() {
 		"Status",
 		"Device",
 		"Status",
+		"Backend",
+		"Zone",
+		"Rack",
 		"Size (G)",
 		"Used (G)",
 		"Free (G)",
@@ -100,7 +106,7 @@ func (s *efesStatus) Print() {
 		"Last update",
 	})
 	table.SetFooter([]string{
-		"", "", "",
+		"", "", "", "", "", "",
 		"Total:",
 		humanize.Comma(totalSize),
 		humanize.Comma(totalUsed),
@@ -118,6 +124,9 @@ func (s *efesStatus) Print() {
 			d.HostStatus,
 			strconv.FormatInt(d.Devid, 10),
 			d.Status,
+			d.Backend,
+			d.Zone,
+			d.Rack,
 			d.Size(),
 			d.Used(),
 			d.Free(),
@@ -132,18 +141,43 @@ func (s *efesStatus) Print() {
 }
 
 func (c *Client) Status(sortBy string) (*efesStatus, error) {
+	return c.StatusContext(context.Background(), sortBy)
+}
+
+// StatusContext is Status with a caller-supplied context. get-devices and
+// get-hosts are fetched concurrently and share ctx, so cancelling it (or
+// hitting the deadline set with SetReadDeadline) aborts both together
+// instead of leaving the second request to hang after the first fails.
+//
+// Print does not highlight under-replicated-by-zone keys: get-devices and
+// get-hosts only describe devices and hosts, not which keys live on which
+// device, so there is no replica-per-key data here to diff across zones.
+// Surfacing that would need a tracker endpoint that reports per-key
+// replica placement.
+func (c *Client) StatusContext(ctx context.Context, sortBy string) (*efesStatus, error) {
+	ctx, cancel := c.readContext(ctx)
+	defer cancel()
+
 	ret := &efesStatus{
 		devices: make([]deviceStatus, 0),
 	}
+
 	var devices GetDevices
-	err := c.request(http.MethodGet, "get-devices", nil, &devices)
-	if err != nil {
-		return nil, err
-	}
 	var hosts GetHosts
-	err = c.request(http.MethodGet, "get-hosts", nil, &hosts)
-	if err != nil {
-		return nil, err
+	errc := make(chan error, 2)
+	go func() {
+		_, err := c.request(ctx, http.MethodGet, "get-devices", nil, &devices)
+		errc <- err
+	}()
+	go func() {
+		_, err := c.request(ctx, http.MethodGet, "get-hosts", nil, &hosts)
+		errc <- err
+	}()
+	for i := 0; i < 2; i++ {
+		if err := <-errc; err != nil {
+			cancel()
+			return nil, err
+		}
 	}
 	hostsByID := make(map[int64]Host)
 	for _, h := range hosts.Hosts {
@@ -153,16 +187,19 @@ func (c *Client) Status(sortBy string) (*efesStatus, error) {
 		if d.Status == "dead" {
 			continue
 		}
-		var hostname string
-		var hostStatus string
+		var hostname, hostStatus, zone, rack string
 		if h, ok := hostsByID[d.Hostid]; ok {
 			hostname = h.Hostname
 			hostStatus = h.Status
+			zone = h.Zone
+			rack = h.Rack
 		}
 		ds := deviceStatus{
 			Device:     d,
 			Hostname:   hostname,
 			HostStatus: hostStatus,
+			Zone:       zone,
+			Rack:       rack,
 			UpdatedAt:  time.Unix(d.UpdatedAt, 0),
 		}
 		ret.devices = append(ret.devices, ds)
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/unix"
+)
+
+// LocalConfig holds configuration for a LocalStorage device.
+type LocalConfig struct {
+	Path string `toml:"path"`
+}
+
+// LocalStorage stores objects as regular files under a root directory on
+// local disk. This is the original Efes storage behavior, now behind the
+// Storage interface.
+type LocalStorage struct {
+	root string
+}
+
+// NewLocalStorage returns a Storage backed by the local filesystem at
+// cfg.Path.
+func NewLocalStorage(cfg LocalConfig) (*LocalStorage, error) {
+	return &LocalStorage{root: cfg.Path}, nil
+}
+
+func (l *LocalStorage) fullPath(path string) string {
+	return filepath.Join(l.root, path)
+}
+
+// Open returns a reader for the file at path.
+func (l *LocalStorage) Open(path string) (io.ReadCloser, error) {
+	return os.Open(l.fullPath(path))
+}
+
+// Create returns a writer for the file at path, creating parent
+// directories and truncating any existing file. size is ignored; it is
+// only meaningful to backends that must pre-declare object size.
+func (l *LocalStorage) Create(path string, size int64) (io.WriteCloser, error) {
+	full := l.fullPath(path)
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		return nil, err
+	}
+	return os.Create(full)
+}
+
+// Stat returns the size in bytes of the file at path.
+func (l *LocalStorage) Stat(path string) (int64, error) {
+	fi, err := os.Stat(l.fullPath(path))
+	if err != nil {
+		return 0, err
+	}
+	return fi.Size(), nil
+}
+
+// Remove deletes the file at path.
+func (l *LocalStorage) Remove(path string) error {
+	return os.Remove(l.fullPath(path))
+}
+
+// List returns every file path under prefix, relative to the device root.
+func (l *LocalStorage) List(prefix string) ([]string, error) {
+	var paths []string
+	root := l.fullPath(prefix)
+	err := filepath.Walk(root, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(l.root, p)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+// FreeBytes returns the free space on the filesystem backing the device,
+// via statfs.
+func (l *LocalStorage) FreeBytes() (int64, error) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(l.root, &stat); err != nil {
+		return 0, err
+	}
+	return int64(stat.Bfree) * int64(stat.Bsize), nil
+}
+
+// TotalBytes returns the total size of the filesystem backing the device,
+// via statfs.
+func (l *LocalStorage) TotalBytes() (int64, error) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(l.root, &stat); err != nil {
+		return 0, err
+	}
+	return int64(stat.Blocks) * int64(stat.Bsize), nil
+}
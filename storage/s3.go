@@ -0,0 +1,172 @@
+package storage
+
+import (
+	"io"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+// S3Config holds configuration for an S3Storage device. QuotaBytes stands
+// in for the capacity a local disk would report via statfs, since a
+// bucket has no fixed size of its own.
+type S3Config struct {
+	Bucket     string `toml:"bucket"`
+	Prefix     string `toml:"prefix"`
+	Region     string `toml:"region"`
+	Endpoint   string `toml:"endpoint"`
+	QuotaBytes int64  `toml:"quota_bytes"`
+}
+
+// S3Storage maps a device to a prefix inside an S3 bucket, so the server
+// can run an object-storage tier alongside LocalStorage devices behind
+// the same Storage interface.
+type S3Storage struct {
+	cfg      S3Config
+	client   *s3.S3
+	uploader *s3manager.Uploader
+}
+
+// NewS3Storage returns a Storage backed by the S3 bucket and prefix in cfg.
+func NewS3Storage(cfg S3Config) (*S3Storage, error) {
+	awsCfg := aws.NewConfig().WithRegion(cfg.Region)
+	if cfg.Endpoint != "" {
+		awsCfg = awsCfg.WithEndpoint(cfg.Endpoint)
+	}
+	sess, err := session.NewSession(awsCfg)
+	if err != nil {
+		return nil, err
+	}
+	client := s3.New(sess)
+	return &S3Storage{
+		cfg:      cfg,
+		client:   client,
+		uploader: s3manager.NewUploaderWithClient(client),
+	}, nil
+}
+
+func (s *S3Storage) key(path string) string {
+	return s.cfg.Prefix + path
+}
+
+// Open returns a reader that streams the S3 object at path directly from
+// the response body, without buffering it in memory.
+func (s *S3Storage) Open(path string) (io.ReadCloser, error) {
+	out, err := s.client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(s.cfg.Bucket),
+		Key:    aws.String(s.key(path)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.Body, nil
+}
+
+// Create returns a writer that streams written bytes into an S3 multipart
+// upload as they arrive, via an io.Pipe feeding the s3manager uploader in
+// a background goroutine, rather than buffering the whole object first.
+func (s *S3Storage) Create(path string, size int64) (io.WriteCloser, error) {
+	pr, pw := io.Pipe()
+	w := &s3Writer{pw: pw, done: make(chan error, 1)}
+	go func() {
+		_, err := s.uploader.Upload(&s3manager.UploadInput{
+			Bucket: aws.String(s.cfg.Bucket),
+			Key:    aws.String(s.key(path)),
+			Body:   pr,
+		})
+		pr.CloseWithError(err)
+		w.done <- err
+	}()
+	return w, nil
+}
+
+type s3Writer struct {
+	pw   *io.PipeWriter
+	done chan error
+}
+
+func (w *s3Writer) Write(p []byte) (int, error) {
+	return w.pw.Write(p)
+}
+
+func (w *s3Writer) Close() error {
+	if err := w.pw.Close(); err != nil {
+		return err
+	}
+	return <-w.done
+}
+
+// Stat returns the size in bytes of the S3 object at path.
+func (s *S3Storage) Stat(path string) (int64, error) {
+	out, err := s.client.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(s.cfg.Bucket),
+		Key:    aws.String(s.key(path)),
+	})
+	if err != nil {
+		return 0, err
+	}
+	return aws.Int64Value(out.ContentLength), nil
+}
+
+// Remove deletes the S3 object at path.
+func (s *S3Storage) Remove(path string) error {
+	_, err := s.client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(s.cfg.Bucket),
+		Key:    aws.String(s.key(path)),
+	})
+	return err
+}
+
+// List returns every object path under prefix, relative to the device's
+// configured S3 prefix.
+func (s *S3Storage) List(prefix string) ([]string, error) {
+	var paths []string
+	err := s.client.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket: aws.String(s.cfg.Bucket),
+		Prefix: aws.String(s.key(prefix)),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, obj := range page.Contents {
+			paths = append(paths, (*obj.Key)[len(s.cfg.Prefix):])
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+// FreeBytes returns the configured quota minus bytes currently used,
+// since S3 buckets have no intrinsic capacity to query.
+func (s *S3Storage) FreeBytes() (int64, error) {
+	used, err := s.usedBytes()
+	if err != nil {
+		return 0, err
+	}
+	free := s.cfg.QuotaBytes - used
+	if free < 0 {
+		free = 0
+	}
+	return free, nil
+}
+
+// TotalBytes returns the configured quota for this device.
+func (s *S3Storage) TotalBytes() (int64, error) {
+	return s.cfg.QuotaBytes, nil
+}
+
+func (s *S3Storage) usedBytes() (int64, error) {
+	var used int64
+	err := s.client.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket: aws.String(s.cfg.Bucket),
+		Prefix: aws.String(s.cfg.Prefix),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, obj := range page.Contents {
+			used += aws.Int64Value(obj.Size)
+		}
+		return true
+	})
+	return used, err
+}
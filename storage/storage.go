@@ -0,0 +1,60 @@
+// Package storage defines the backend a per-host Efes server uses to keep
+// file bytes, so a single cluster can mix cheap object storage tiers with
+// hot local disks behind the same device ID space.
+package storage
+
+import "io"
+
+// Storage is implemented by each device backend a server can be configured
+// with. Paths are relative to the device; callers (the server's HTTP
+// handlers) are responsible for namespacing keys.
+type Storage interface {
+	// Open returns a reader for the object at path.
+	Open(path string) (io.ReadCloser, error)
+	// Create returns a writer for the object at path, creating or
+	// truncating it. size is the expected length in bytes; neither
+	// LocalStorage nor S3Storage uses or enforces it.
+	Create(path string, size int64) (io.WriteCloser, error)
+	// Stat returns the size in bytes of the object at path.
+	Stat(path string) (int64, error)
+	// Remove deletes the object at path.
+	Remove(path string) error
+	// List returns the paths of every object stored under prefix.
+	List(prefix string) ([]string, error)
+	// FreeBytes returns the bytes currently free on the device.
+	FreeBytes() (int64, error)
+	// TotalBytes returns the device's total configured capacity in bytes.
+	TotalBytes() (int64, error)
+}
+
+// Config is the backend-specific configuration for a single [[devices]]
+// entry in ServerConfig. Exactly one of Local/S3 should be set, selected
+// by Backend.
+type Config struct {
+	Devid   int64  `toml:"devid"`
+	Backend string `toml:"backend"` // "local" or "s3"
+	Local   LocalConfig
+	S3      S3Config
+}
+
+// New builds the Storage implementation described by cfg.
+func New(cfg Config) (Storage, error) {
+	switch cfg.Backend {
+	case "", "local":
+		return NewLocalStorage(cfg.Local)
+	case "s3":
+		return NewS3Storage(cfg.S3)
+	default:
+		return nil, &UnknownBackendError{Backend: cfg.Backend}
+	}
+}
+
+// UnknownBackendError is returned by New when a [[devices]] entry names a
+// backend Efes doesn't implement.
+type UnknownBackendError struct {
+	Backend string
+}
+
+func (e *UnknownBackendError) Error() string {
+	return "storage: unknown backend " + e.Backend
+}
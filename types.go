@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// G is the number of bytes in a gibibyte, used to render the status
+// table's byte columns.
+const G = 1 << 30
+
+// Device is a single storage device as reported by the tracker's
+// get-devices endpoint.
+type Device struct {
+	Devid         int64  `json:"devid"`
+	Hostid        int64  `json:"hostid"`
+	Status        string `json:"status"`
+	Backend       string `json:"backend"`
+	BytesTotal    *int64 `json:"bytes_total"`
+	BytesUsed     *int64 `json:"bytes_used"`
+	BytesFree     *int64 `json:"bytes_free"`
+	IoUtilization *int64 `json:"io_utilization"`
+	UpdatedAt     int64  `json:"updated_at"`
+}
+
+// GetDevices is the tracker's reply to a get-devices request.
+type GetDevices struct {
+	Devices []Device `json:"devices"`
+}
+
+// Host is a single host as reported by the tracker's get-hosts endpoint.
+type Host struct {
+	Hostid   int64  `json:"hostid"`
+	Hostname string `json:"hostname"`
+	Status   string `json:"status"`
+	Zone     string `json:"zone"`
+	Rack     string `json:"rack"`
+}
+
+// GetHosts is the tracker's reply to a get-hosts request.
+type GetHosts struct {
+	Hosts []Host `json:"hosts"`
+}
+
+// ClientError is returned by Client.request when the tracker answers with
+// a non-2xx status.
+type ClientError struct {
+	Code int
+	Msg  string
+}
+
+func (e *ClientError) Error() string {
+	return fmt.Sprintf("efes: tracker returned %d: %s", e.Code, e.Msg)
+}
+
+func checkResponseError(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	body, _ := io.ReadAll(resp.Body)
+	return &ClientError{Code: resp.StatusCode, Msg: string(body)}
+}
+
+type byHostname struct{ devices []deviceStatus }
+
+func (b byHostname) Len() int      { return len(b.devices) }
+func (b byHostname) Swap(i, j int) { b.devices[i], b.devices[j] = b.devices[j], b.devices[i] }
+func (b byHostname) Less(i, j int) bool {
+	return b.devices[i].Hostname < b.devices[j].Hostname
+}
+
+type byDevID struct{ devices []deviceStatus }
+
+func (b byDevID) Len() int      { return len(b.devices) }
+func (b byDevID) Swap(i, j int) { b.devices[i], b.devices[j] = b.devices[j], b.devices[i] }
+func (b byDevID) Less(i, j int) bool {
+	return b.devices[i].Devid < b.devices[j].Devid
+}
+
+type bySize struct{ devices []deviceStatus }
+
+func (b bySize) Len() int      { return len(b.devices) }
+func (b bySize) Swap(i, j int) { b.devices[i], b.devices[j] = b.devices[j], b.devices[i] }
+func (b bySize) Less(i, j int) bool {
+	return int64PtrValue(b.devices[i].BytesTotal) < int64PtrValue(b.devices[j].BytesTotal)
+}
+
+type byUsed struct{ devices []deviceStatus }
+
+func (b byUsed) Len() int      { return len(b.devices) }
+func (b byUsed) Swap(i, j int) { b.devices[i], b.devices[j] = b.devices[j], b.devices[i] }
+func (b byUsed) Less(i, j int) bool {
+	return int64PtrValue(b.devices[i].BytesUsed) < int64PtrValue(b.devices[j].BytesUsed)
+}
+
+type byFree struct{ devices []deviceStatus }
+
+func (b byFree) Len() int      { return len(b.devices) }
+func (b byFree) Swap(i, j int) { b.devices[i], b.devices[j] = b.devices[j], b.devices[i] }
+func (b byFree) Less(i, j int) bool {
+	return int64PtrValue(b.devices[i].BytesFree) < int64PtrValue(b.devices[j].BytesFree)
+}
+
+func int64PtrValue(p *int64) int64 {
+	if p == nil {
+		return 0
+	}
+	return *p
+}